@@ -0,0 +1,138 @@
+// Copyright 2017 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package monitoring provides abstracted support for monitoring/instrumentation.
+package monitoring
+
+import "time"
+
+// MetricFactory allows the creation of monitoring metric objects, abstracting
+// away the underlying monitoring library used.
+type MetricFactory interface {
+	// NewCounter creates a new Counter object.
+	NewCounter(name, help string, labelNames []string) Counter
+	// NewGauge creates a new Gauge object.
+	NewGauge(name, help string, labelNames []string) Gauge
+	// NewHistogram creates a new Histogram object.
+	NewHistogram(name, help string, labelNames []string) Histogram
+	// NewHistogramWithOpts creates a new Histogram object, customizing its
+	// bucket layout via opts. A backend that can't honour a given option
+	// (e.g. no native histogram support) ignores it.
+	NewHistogramWithOpts(name, help string, labelNames []string, opts ...HistogramOption) Histogram
+	// NewSummary creates a new Summary object, streaming quantile estimates
+	// for the given objectives over a sliding maxAge window split into
+	// ageBuckets buckets.
+	NewSummary(name, help string, labelNames []string, objectives map[float64]float64, maxAge time.Duration, ageBuckets uint32) Summary
+}
+
+// HistogramOpts carries the bucket-layout choices accepted by
+// NewHistogramWithOpts, applied by HistogramOptions.
+type HistogramOpts struct {
+	// Buckets overrides the backend's default bucket boundaries.
+	Buckets []float64
+	// NativeHistogramBucketFactor, if non-zero, opts into native (sparse)
+	// histograms with this bucket growth factor.
+	NativeHistogramBucketFactor float64
+	// NativeHistogramMaxBucketNumber bounds the number of native buckets.
+	NativeHistogramMaxBucketNumber uint32
+	// NativeHistogramMinResetDuration is the minimum time between automatic
+	// resets of the native bucket counts.
+	NativeHistogramMinResetDuration time.Duration
+}
+
+// HistogramOption customizes a HistogramOpts value.
+type HistogramOption func(*HistogramOpts)
+
+// Counter represents a monotonically increasing value that can be
+// broken down by label values.
+type Counter interface {
+	// Inc adds 1 to a counter.
+	Inc(labelVals ...string)
+	// Add adds the given amount to a counter.
+	Add(val float64, labelVals ...string)
+	// Value returns the current amount of a counter.
+	Value(labelVals ...string) float64
+}
+
+// Gauge represents a value that can go up and down, broken down by
+// label values.
+type Gauge interface {
+	// Inc adds 1 to a gauge.
+	Inc(labelVals ...string)
+	// Dec subtracts 1 from a gauge.
+	Dec(labelVals ...string)
+	// Add adds the given amount to a gauge.
+	Add(val float64, labelVals ...string)
+	// Set sets the value of a gauge.
+	Set(val float64, labelVals ...string)
+	// Value returns the current amount of a gauge.
+	Value(labelVals ...string) float64
+}
+
+// Histogram records a distribution of observations, broken down by
+// label values.
+type Histogram interface {
+	// Observe adds a single observation to the histogram.
+	Observe(val float64, labelVals ...string)
+	// Info returns the count and sum of observations for the histogram.
+	Info(labelVals ...string) (uint64, float64)
+	// Timer starts timing a code block, recording its duration as an
+	// observation when the returned Timer is stopped.
+	Timer(labelVals ...string) Timer
+}
+
+// Summary records a distribution of observations as a set of streaming
+// quantile estimates, broken down by label values.
+type Summary interface {
+	// Observe adds a single observation to the summary.
+	Observe(val float64, labelVals ...string)
+	// Info returns the count and sum of observations for the summary.
+	Info(labelVals ...string) (uint64, float64)
+	// Quantile returns the estimated value at quantile q (e.g. 0.99 for the
+	// 99th percentile) of the observations in the summary's current
+	// sliding time window.
+	Quantile(q float64, labelVals ...string) float64
+	// Timer starts timing a code block, recording its duration as an
+	// observation when the returned Timer is stopped.
+	Timer(labelVals ...string) Timer
+}
+
+// Observer is implemented by metrics that record individual observations,
+// such as Histogram and Summary.
+type Observer interface {
+	Observe(val float64, labelVals ...string)
+}
+
+// Timer measures the duration of a code block, recording it as an
+// observation against the Observer it was created from once stopped.
+type Timer interface {
+	// ObserveDuration records the duration elapsed since the Timer was
+	// created as an observation, and returns that duration.
+	ObserveDuration() time.Duration
+	// Stop is a synonym for ObserveDuration, for callers (e.g. via defer)
+	// that don't need the returned duration.
+	Stop()
+}
+
+// Time starts timing a code block against o, returning a function that
+// records the elapsed time as an observation when called. It is intended to
+// be used with defer:
+//
+//	defer monitoring.Time(histogram, labelVal1, labelVal2)()
+func Time(o Observer, labelVals ...string) func() {
+	start := time.Now()
+	return func() {
+		o.Observe(time.Since(start).Seconds(), labelVals...)
+	}
+}
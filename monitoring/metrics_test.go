@@ -0,0 +1,55 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitoring
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeObserver records the label values and value of its last Observe call.
+type fakeObserver struct {
+	labelVals []string
+	val       float64
+	calls     int
+}
+
+func (f *fakeObserver) Observe(val float64, labelVals ...string) {
+	f.calls++
+	f.val = val
+	f.labelVals = labelVals
+}
+
+func TestTime(t *testing.T) {
+	o := &fakeObserver{}
+	stop := Time(o, "a", "b")
+	if o.calls != 0 {
+		t.Fatalf("Observe called %d times before stop func was invoked, want 0", o.calls)
+	}
+
+	time.Sleep(time.Millisecond)
+	stop()
+
+	if o.calls != 1 {
+		t.Fatalf("Observe called %d times, want 1", o.calls)
+	}
+	if o.val <= 0 {
+		t.Errorf("observed duration = %v, want > 0", o.val)
+	}
+	want := []string{"a", "b"}
+	if len(o.labelVals) != len(want) || o.labelVals[0] != want[0] || o.labelVals[1] != want[1] {
+		t.Errorf("observed labelVals = %v, want %v", o.labelVals, want)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHistogramNativeBucketsClassicOnly(t *testing.T) {
+	mf := MetricFactory{Registerer: prometheus.NewRegistry()}
+	h := mf.NewHistogramWithOpts("test_histogram_classic", "help", nil, Buckets([]float64{1, 2, 4}))
+	h.Observe(1.5)
+
+	hist := h.(*Histogram).NativeBuckets()
+	if hist == nil {
+		t.Fatal("NativeBuckets() = nil, want a populated dto.Histogram")
+	}
+	if got, want := hist.GetSampleCount(), uint64(1); got != want {
+		t.Errorf("SampleCount = %d, want %d", got, want)
+	}
+	if len(hist.GetBucket()) == 0 {
+		t.Errorf("got no classic buckets, want some from the explicit Buckets() layout")
+	}
+}
+
+func TestHistogramNativeBucketsOptedIn(t *testing.T) {
+	mf := MetricFactory{Registerer: prometheus.NewRegistry()}
+	h := mf.NewHistogramWithOpts("test_histogram_native", "help", nil, NativeHistogram(1.1, 100, 0))
+	for i := 0; i < 10; i++ {
+		h.Observe(float64(i))
+	}
+
+	hist := h.(*Histogram).NativeBuckets()
+	if hist == nil {
+		t.Fatal("NativeBuckets() = nil, want a populated dto.Histogram")
+	}
+	if hist.GetZeroCount() == 0 && len(hist.GetPositiveDelta()) == 0 {
+		t.Errorf("got no native bucket data, want some after opting into NativeHistogram")
+	}
+}
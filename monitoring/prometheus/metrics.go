@@ -18,16 +18,62 @@ package prometheus
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/google/trillian/monitoring"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	dto "github.com/prometheus/client_model/go"
 )
 
-// MetricFactory allows the creation of Prometheus-based metrics.
+// MetricFactory allows the creation of Prometheus-based metrics. Registerer
+// and Gatherer default to the process-global registry when left nil.
 type MetricFactory struct {
-	Prefix string
+	Prefix     string
+	Registerer prometheus.Registerer
+	Gatherer   prometheus.Gatherer
+}
+
+var _ monitoring.MetricFactory = MetricFactory{}
+
+// registerer returns the factory's Registerer, defaulting to the
+// process-global registerer.
+func (pmf MetricFactory) registerer() prometheus.Registerer {
+	if pmf.Registerer != nil {
+		return pmf.Registerer
+	}
+	return prometheus.DefaultRegisterer
+}
+
+// gatherer returns the factory's Gatherer, defaulting to the process-global
+// gatherer.
+func (pmf MetricFactory) gatherer() prometheus.Gatherer {
+	if pmf.Gatherer != nil {
+		return pmf.Gatherer
+	}
+	return prometheus.DefaultGatherer
+}
+
+// Handler returns an http.Handler that serves a scrape of the factory's
+// Gatherer.
+func (pmf MetricFactory) Handler() http.Handler {
+	return promhttp.HandlerFor(pmf.gatherer(), promhttp.HandlerOpts{})
+}
+
+// registerOrReuse registers c against reg, reusing an already-registered
+// collector with the same descriptors instead of erroring. Any other
+// registration error (e.g. the same name with a different help string or
+// label names) panics.
+func registerOrReuse(reg prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
+	if err := reg.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
 }
 
 // NewCounter creates a new Counter object backed by Prometheus.
@@ -38,8 +84,8 @@ func (pmf MetricFactory) NewCounter(name, help string, labelNames []string) moni
 				Name: pmf.Prefix + name,
 				Help: help,
 			})
-		prometheus.MustRegister(counter)
-		return &Counter{single: counter}
+		c := registerOrReuse(pmf.registerer(), counter).(prometheus.Counter)
+		return &Counter{single: c}
 	}
 
 	vec := prometheus.NewCounterVec(
@@ -48,8 +94,8 @@ func (pmf MetricFactory) NewCounter(name, help string, labelNames []string) moni
 			Help: help,
 		},
 		labelNames)
-	prometheus.MustRegister(vec)
-	return &Counter{labelNames: labelNames, vec: vec}
+	v := registerOrReuse(pmf.registerer(), vec).(*prometheus.CounterVec)
+	return &Counter{labelNames: labelNames, vec: v}
 }
 
 // NewGauge creates a new Gauge object backed by Prometheus.
@@ -60,8 +106,8 @@ func (pmf MetricFactory) NewGauge(name, help string, labelNames []string) monito
 				Name: pmf.Prefix + name,
 				Help: help,
 			})
-		prometheus.MustRegister(gauge)
-		return &Gauge{single: gauge}
+		g := registerOrReuse(pmf.registerer(), gauge).(prometheus.Gauge)
+		return &Gauge{single: g}
 	}
 	vec := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -69,29 +115,189 @@ func (pmf MetricFactory) NewGauge(name, help string, labelNames []string) monito
 			Help: help,
 		},
 		labelNames)
-	prometheus.MustRegister(vec)
-	return &Gauge{labelNames: labelNames, vec: vec}
+	v := registerOrReuse(pmf.registerer(), vec).(*prometheus.GaugeVec)
+	return &Gauge{labelNames: labelNames, vec: v}
 }
 
-// NewHistogram creates a new Histogram object backed by Prometheus.
+// NewHistogram creates a new Histogram object backed by Prometheus, using the
+// default Prometheus bucket layout.
 func (pmf MetricFactory) NewHistogram(name, help string, labelNames []string) monitoring.Histogram {
+	return pmf.NewHistogramWithOpts(name, help, labelNames)
+}
+
+// HistogramOption is an alias of monitoring.HistogramOption, kept here for
+// convenience since most callers of this package only import "prometheus".
+type HistogramOption = monitoring.HistogramOption
+
+// Buckets sets an explicit bucket layout for the histogram, overriding the
+// backend's default buckets.
+func Buckets(buckets []float64) HistogramOption {
+	return func(opts *monitoring.HistogramOpts) {
+		opts.Buckets = buckets
+	}
+}
+
+// LinearBuckets sets a linear bucket layout; see prometheus.LinearBuckets.
+func LinearBuckets(start, width float64, count int) HistogramOption {
+	return Buckets(prometheus.LinearBuckets(start, width, count))
+}
+
+// ExponentialBuckets sets an exponential bucket layout; see
+// prometheus.ExponentialBuckets.
+func ExponentialBuckets(start, factor float64, count int) HistogramOption {
+	return Buckets(prometheus.ExponentialBuckets(start, factor, count))
+}
+
+// NativeHistogram opts the histogram into Prometheus native (sparse)
+// histograms. Classic buckets are kept alongside only if Buckets (or one of
+// its builders) is also given; otherwise the histogram has no classic
+// buckets. bucketFactor controls the
+// growth factor between adjacent native buckets, maxBucketNumber bounds how
+// many native buckets are kept before they're merged, and minResetDuration is
+// the minimum time between automatic resets of the native bucket counts.
+func NativeHistogram(bucketFactor float64, maxBucketNumber uint32, minResetDuration time.Duration) HistogramOption {
+	return func(opts *monitoring.HistogramOpts) {
+		opts.NativeHistogramBucketFactor = bucketFactor
+		opts.NativeHistogramMaxBucketNumber = maxBucketNumber
+		opts.NativeHistogramMinResetDuration = minResetDuration
+	}
+}
+
+// NewHistogramWithOpts creates a new Histogram object backed by Prometheus,
+// applying the given options to customize its bucket layout.
+func (pmf MetricFactory) NewHistogramWithOpts(name, help string, labelNames []string, opts ...monitoring.HistogramOption) monitoring.Histogram {
+	var hOpts monitoring.HistogramOpts
+	for _, opt := range opts {
+		opt(&hOpts)
+	}
+	popts := prometheus.HistogramOpts{
+		Name:                            pmf.Prefix + name,
+		Help:                            help,
+		Buckets:                         hOpts.Buckets,
+		NativeHistogramBucketFactor:     hOpts.NativeHistogramBucketFactor,
+		NativeHistogramMaxBucketNumber:  hOpts.NativeHistogramMaxBucketNumber,
+		NativeHistogramMinResetDuration: hOpts.NativeHistogramMinResetDuration,
+	}
+
 	if labelNames == nil || len(labelNames) == 0 {
-		histogram := prometheus.NewHistogram(
-			prometheus.HistogramOpts{
-				Name: pmf.Prefix + name,
-				Help: help,
-			})
-		prometheus.MustRegister(histogram)
-		return &Histogram{single: histogram}
+		histogram := prometheus.NewHistogram(popts)
+		h := registerOrReuse(pmf.registerer(), histogram).(prometheus.Histogram)
+		return &Histogram{single: h}
 	}
-	vec := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name: pmf.Prefix + name,
-			Help: help,
-		},
-		labelNames)
-	prometheus.MustRegister(vec)
-	return &Histogram{labelNames: labelNames, vec: vec}
+	vec := prometheus.NewHistogramVec(popts, labelNames)
+	v := registerOrReuse(pmf.registerer(), vec).(*prometheus.HistogramVec)
+	return &Histogram{labelNames: labelNames, vec: v}
+}
+
+// defaultObjectives are the quantile objectives used by NewSummary when the
+// caller does not supply any, chosen to give a reasonable spread for RPC
+// latency style SLIs.
+var defaultObjectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+
+// defaultMaxAge is the sliding time window used by NewSummary when the
+// caller passes a zero maxAge.
+const defaultMaxAge = 10 * time.Minute
+
+// defaultAgeBuckets is the number of buckets used to implement the sliding
+// time window when the caller passes a zero ageBuckets.
+const defaultAgeBuckets = 5
+
+// NewSummary creates a new Summary object backed by Prometheus, streaming
+// quantile estimates for the given objectives over a sliding maxAge window
+// split into ageBuckets buckets. A zero objectives map, maxAge or ageBuckets
+// falls back to sane defaults (0.5/0.9/0.99 objectives over a 10-minute
+// window).
+func (pmf MetricFactory) NewSummary(name, help string, labelNames []string, objectives map[float64]float64, maxAge time.Duration, ageBuckets uint32) monitoring.Summary {
+	if len(objectives) == 0 {
+		objectives = defaultObjectives
+	}
+	if maxAge == 0 {
+		maxAge = defaultMaxAge
+	}
+	if ageBuckets == 0 {
+		ageBuckets = defaultAgeBuckets
+	}
+	sOpts := prometheus.SummaryOpts{
+		Name:       pmf.Prefix + name,
+		Help:       help,
+		Objectives: objectives,
+		MaxAge:     maxAge,
+		AgeBuckets: ageBuckets,
+	}
+
+	if labelNames == nil || len(labelNames) == 0 {
+		summary := prometheus.NewSummary(sOpts)
+		s := registerOrReuse(pmf.registerer(), summary).(prometheus.Summary)
+		return &Summary{single: s}
+	}
+	vec := prometheus.NewSummaryVec(sOpts, labelNames)
+	v := registerOrReuse(pmf.registerer(), vec).(*prometheus.SummaryVec)
+	return &Summary{labelNames: labelNames, vec: v}
+}
+
+// Summary is a wrapper around a Prometheus Summary or SummaryVec object.
+type Summary struct {
+	labelNames []string
+	single     prometheus.Summary
+	vec        *prometheus.SummaryVec
+}
+
+// Observe adds a single observation to the summary.
+func (m *Summary) Observe(val float64, labelVals ...string) {
+	if m.vec != nil {
+		m.vec.With(labelsFor(m.labelNames, labelVals)).Observe(val)
+	} else {
+		m.single.Observe(val)
+	}
+}
+
+// Info returns the count and sum of observations for the summary.
+func (m *Summary) Info(labelVals ...string) (uint64, float64) {
+	metricpb, ok := m.write(labelVals...)
+	if !ok {
+		return 0, 0.0
+	}
+	sumVal := metricpb.GetSummary()
+	if sumVal == nil {
+		glog.Errorf("summary field missing")
+		return 0, 0.0
+	}
+	return sumVal.GetSampleCount(), sumVal.GetSampleSum()
+}
+
+// Quantile returns the estimated value at quantile q for the summary, or 0
+// if q was not one of the objectives the summary was created with.
+func (m *Summary) Quantile(q float64, labelVals ...string) float64 {
+	metricpb, ok := m.write(labelVals...)
+	if !ok {
+		return 0.0
+	}
+	sumVal := metricpb.GetSummary()
+	if sumVal == nil {
+		glog.Errorf("summary field missing")
+		return 0.0
+	}
+	for _, qv := range sumVal.GetQuantile() {
+		if qv.GetQuantile() == q {
+			return qv.GetValue()
+		}
+	}
+	return 0.0
+}
+
+func (m *Summary) write(labelVals ...string) (*dto.Metric, bool) {
+	var metric prometheus.Metric
+	if m.vec != nil {
+		metric = m.vec.With(labelsFor(m.labelNames, labelVals)).(prometheus.Metric)
+	} else {
+		metric = m.single
+	}
+	metricpb := &dto.Metric{}
+	if err := metric.Write(metricpb); err != nil {
+		glog.Errorf("failed to Write metric: %v", err)
+		return nil, false
+	}
+	return metricpb, true
 }
 
 // Counter is a wrapper around a Prometheus Counter or CounterVec object.
@@ -222,7 +428,7 @@ func (m *Histogram) Observe(val float64, labelVals ...string) {
 func (m *Histogram) Info(labelVals ...string) (uint64, float64) {
 	var metric prometheus.Metric
 	if m.vec != nil {
-		metric = m.vec.MetricVec.With(labelsFor(m.labelNames, labelVals)).(prometheus.Metric)
+		metric = m.vec.With(labelsFor(m.labelNames, labelVals)).(prometheus.Metric)
 	} else {
 		metric = m.single
 	}
@@ -239,6 +445,65 @@ func (m *Histogram) Info(labelVals ...string) (uint64, float64) {
 	return histVal.GetSampleCount(), histVal.GetSampleSum()
 }
 
+// NativeBuckets returns the raw dto.Histogram for the metric, exposing the
+// native (sparse) bucket data alongside the classic buckets. It returns nil
+// only if the underlying metric could not be written out; a histogram created
+// without native histogram support still yields its classic buckets, with no
+// native bucket data.
+func (m *Histogram) NativeBuckets(labelVals ...string) *dto.Histogram {
+	var metric prometheus.Metric
+	if m.vec != nil {
+		metric = m.vec.With(labelsFor(m.labelNames, labelVals)).(prometheus.Metric)
+	} else {
+		metric = m.single
+	}
+	var metricpb dto.Metric
+	if err := metric.Write(&metricpb); err != nil {
+		glog.Errorf("failed to Write metric: %v", err)
+		return nil
+	}
+	return metricpb.GetHistogram()
+}
+
+// Timer is a handle returned by Histogram.Timer and Summary.Timer that
+// records the elapsed time as an observation once stopped.
+type Timer struct {
+	start   time.Time
+	observe func(val float64)
+}
+
+// newTimer returns a Timer that records its elapsed duration, in seconds,
+// via observe.
+func newTimer(observe func(val float64)) *Timer {
+	return &Timer{start: time.Now(), observe: observe}
+}
+
+// ObserveDuration records the duration elapsed since the Timer was created
+// as an observation, and returns that duration.
+func (t *Timer) ObserveDuration() time.Duration {
+	d := time.Since(t.start)
+	t.observe(d.Seconds())
+	return d
+}
+
+// Stop is a synonym for ObserveDuration, for callers that don't need the
+// returned duration.
+func (t *Timer) Stop() {
+	t.ObserveDuration()
+}
+
+// Timer starts timing a code block, recording its duration as an
+// observation against the histogram when the returned Timer is stopped.
+func (m *Histogram) Timer(labelVals ...string) monitoring.Timer {
+	return newTimer(func(val float64) { m.Observe(val, labelVals...) })
+}
+
+// Timer starts timing a code block, recording its duration as an
+// observation against the summary when the returned Timer is stopped.
+func (m *Summary) Timer(labelVals ...string) monitoring.Timer {
+	return newTimer(func(val float64) { m.Observe(val, labelVals...) })
+}
+
 func labelsFor(names, values []string) prometheus.Labels {
 	if len(names) != len(values) {
 		panic(fmt.Sprintf("got %d (%v) values for %d labels (%v)", len(values), values, len(names), names))
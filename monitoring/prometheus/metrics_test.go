@@ -0,0 +1,57 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegisterOrReuse(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c1 := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_counter", Help: "help"})
+	c2 := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_counter", Help: "help"})
+
+	got1 := registerOrReuse(reg, c1)
+	if got1 != c1 {
+		t.Errorf("registerOrReuse(reg, c1) = %v, want c1 for a first-time registration", got1)
+	}
+
+	got2 := registerOrReuse(reg, c2)
+	if got2 != c1 {
+		t.Errorf("registerOrReuse(reg, c2) = %v, want the already-registered c1", got2)
+	}
+}
+
+func TestMetricFactoryUsesInjectedRegisterer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mf := MetricFactory{Registerer: reg}
+	mf.NewCounter("test_injected_counter", "help", nil)
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("reg.Gather() failed: %v", err)
+	}
+	found := false
+	for _, m := range mfs {
+		if m.GetName() == "test_injected_counter" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("test_injected_counter not registered against the injected registry")
+	}
+}
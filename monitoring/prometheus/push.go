@@ -0,0 +1,86 @@
+// Copyright 2021 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PushSink periodically pushes the metrics gathered from a registry to a
+// Prometheus push gateway, for short-lived Trillian binaries whose metrics
+// would otherwise never be scraped.
+type PushSink struct {
+	pusher *push.Pusher
+	url    string
+	job    string
+}
+
+// NewPushSink creates a PushSink that pushes the metrics gathered from reg to
+// the push gateway at url, under the given job name and grouping labels.
+func NewPushSink(url, job string, grouping map[string]string, reg *prometheus.Registry) *PushSink {
+	pusher := push.New(url, job).Gatherer(reg)
+	for k, v := range grouping {
+		pusher = pusher.Grouping(k, v)
+	}
+	return &PushSink{pusher: pusher, url: url, job: job}
+}
+
+// Push pushes the collected metrics, replacing any previously pushed metrics
+// with the same job and grouping labels.
+func (s *PushSink) Push(ctx context.Context) error {
+	return s.pusher.PushContext(ctx)
+}
+
+// Add pushes the collected metrics, adding them to (rather than replacing)
+// any previously pushed metrics with the same job and grouping labels.
+func (s *PushSink) Add(ctx context.Context) error {
+	return s.pusher.AddContext(ctx)
+}
+
+// Run pushes the collected metrics every interval until ctx is done, at
+// which point it deletes the pushed group from the gateway so stale metrics
+// don't linger after the binary exits.
+func (s *PushSink) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.pusher.Delete(); err != nil {
+				glog.Errorf("failed to delete push gateway group for job %q: %v", s.job, err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.Push(ctx); err != nil {
+				glog.Errorf("failed to push metrics to %q: %v", s.url, err)
+			}
+		}
+	}
+}
+
+// NewPushMetricFactory creates a MetricFactory backed by a dedicated
+// prometheus.Registry, along with a PushSink that owns that registry and
+// pushes its metrics to the push gateway at url.
+func NewPushMetricFactory(prefix, url, job string, grouping map[string]string) (MetricFactory, *PushSink) {
+	reg := prometheus.NewRegistry()
+	mf := MetricFactory{Prefix: prefix, Registerer: reg, Gatherer: reg}
+	sink := NewPushSink(url, job, grouping, reg)
+	return mf, sink
+}
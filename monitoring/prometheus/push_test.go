@@ -0,0 +1,87 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPushSinkPushAndAdd(t *testing.T) {
+	var gotMethods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethods = append(gotMethods, r.Method)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	mf, sink := NewPushMetricFactory("test_", srv.URL, "test_job", map[string]string{"zone": "xy"})
+	mf.NewCounter("requests", "help", nil).Inc()
+
+	if err := sink.Push(context.Background()); err != nil {
+		t.Fatalf("Push() = %v, want nil", err)
+	}
+	if err := sink.Add(context.Background()); err != nil {
+		t.Fatalf("Add() = %v, want nil", err)
+	}
+
+	want := []string{http.MethodPut, http.MethodPost}
+	if len(gotMethods) != len(want) {
+		t.Fatalf("got methods %v, want %v", gotMethods, want)
+	}
+	for i, m := range want {
+		if gotMethods[i] != m {
+			t.Errorf("request %d method = %q, want %q", i, gotMethods[i], m)
+		}
+	}
+}
+
+func TestPushSinkRunDeletesOnShutdown(t *testing.T) {
+	deleted := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusAccepted)
+			deleted <- struct{}{}
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	_, sink := NewPushMetricFactory("test_", srv.URL, "test_job", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sink.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-deleted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not delete the pushed group on shutdown")
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after ctx was cancelled")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// RuntimeOptions configures RegisterRuntimeCollectors.
+type RuntimeOptions struct {
+	// Version is the release version to report in the build-info metric. If
+	// empty, the main module version from runtime/debug.ReadBuildInfo is
+	// used where available.
+	Version string
+	// Revision is the VCS revision the binary was built from. If empty, the
+	// "vcs.revision" build setting from runtime/debug.ReadBuildInfo is used
+	// where available.
+	Revision string
+}
+
+// RegisterRuntimeCollectors registers the standard Go runtime and process
+// collectors, plus a build-info collector, against the factory's registerer,
+// giving every Trillian binary go_*, process_* and
+// <prefix>build_info{version,revision,go_version} metrics.
+func (pmf MetricFactory) RegisterRuntimeCollectors(opts RuntimeOptions) {
+	reg := pmf.registerer()
+	registerOrReuse(reg, collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	registerOrReuse(reg, collectors.NewGoCollector(
+		collectors.WithGoCollections(collectors.GoRuntimeMetricsCollection)))
+	registerOrReuse(reg, pmf.newBuildInfoCollector(opts))
+}
+
+// newBuildInfoCollector returns a collector exposing a constant '1' gauge
+// labeled with the binary's version, revision and Go version.
+func (pmf MetricFactory) newBuildInfoCollector(opts RuntimeOptions) prometheus.Collector {
+	version, revision := opts.Version, opts.Revision
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if version == "" {
+			version = info.Main.Version
+		}
+		if revision == "" {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" {
+					revision = setting.Value
+				}
+			}
+		}
+	}
+	return prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: pmf.Prefix + "build_info",
+			Help: "A metric with a constant '1' value, labeled by version, revision and the Go version used to build the binary.",
+			ConstLabels: prometheus.Labels{
+				"version":    version,
+				"revision":   revision,
+				"go_version": runtime.Version(),
+			},
+		},
+		func() float64 { return 1 },
+	)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegisterRuntimeCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mf := MetricFactory{Prefix: "test_", Registerer: reg, Gatherer: reg}
+	mf.RegisterRuntimeCollectors(RuntimeOptions{Version: "v1.2.3", Revision: "deadbeef"})
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() failed: %v", err)
+	}
+
+	var sawGo, sawProcess, sawBuildInfo bool
+	for _, m := range mfs {
+		switch {
+		case strings.HasPrefix(m.GetName(), "go_"):
+			sawGo = true
+		case strings.HasPrefix(m.GetName(), "process_"):
+			sawProcess = true
+		case m.GetName() == "test_build_info":
+			sawBuildInfo = true
+			metric := m.GetMetric()[0]
+			labels := map[string]string{}
+			for _, l := range metric.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if got, want := labels["version"], "v1.2.3"; got != want {
+				t.Errorf("build_info version label = %q, want %q", got, want)
+			}
+			if got, want := labels["revision"], "deadbeef"; got != want {
+				t.Errorf("build_info revision label = %q, want %q", got, want)
+			}
+			if got := metric.GetGauge().GetValue(); got != 1 {
+				t.Errorf("build_info gauge value = %v, want 1", got)
+			}
+		}
+	}
+	if !sawGo {
+		t.Error("no go_* metrics registered")
+	}
+	if !sawProcess {
+		t.Error("no process_* metrics registered")
+	}
+	if !sawBuildInfo {
+		t.Error("test_build_info metric not registered")
+	}
+}
+
+func TestRegisterRuntimeCollectorsIdempotent(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mf := MetricFactory{Registerer: reg}
+
+	mf.RegisterRuntimeCollectors(RuntimeOptions{})
+	mf.RegisterRuntimeCollectors(RuntimeOptions{})
+
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("Gather() failed after double registration: %v", err)
+	}
+}
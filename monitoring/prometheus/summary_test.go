@@ -0,0 +1,79 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSummaryInfo(t *testing.T) {
+	mf := MetricFactory{Registerer: prometheus.NewRegistry()}
+	s := mf.NewSummary("test_summary_info", "help", nil, nil, 0, 0)
+
+	if count, sum := s.Info(); count != 0 || sum != 0 {
+		t.Errorf("Info() on empty summary = (%d, %v), want (0, 0)", count, sum)
+	}
+
+	s.Observe(1.0)
+	s.Observe(2.0)
+	s.Observe(3.0)
+
+	count, sum := s.Info()
+	if want := uint64(3); count != want {
+		t.Errorf("Info() count = %d, want %d", count, want)
+	}
+	if want := 6.0; sum != want {
+		t.Errorf("Info() sum = %v, want %v", sum, want)
+	}
+}
+
+func TestSummaryQuantile(t *testing.T) {
+	objectives := map[float64]float64{0.5: 0.05, 0.99: 0.001}
+	mf := MetricFactory{Registerer: prometheus.NewRegistry()}
+	s := mf.NewSummary("test_summary_quantile", "help", nil, objectives, 0, 0)
+
+	for i := 1; i <= 100; i++ {
+		s.Observe(float64(i))
+	}
+
+	for _, q := range []float64{0.5, 0.99} {
+		if got := s.Quantile(q); got == 0 {
+			t.Errorf("Quantile(%v) = 0, want a non-zero estimate", q)
+		}
+	}
+
+	// 0.9 isn't one of the objectives the summary was created with, so it
+	// should report 0 rather than interpolating an estimate.
+	if got := s.Quantile(0.9); got != 0 {
+		t.Errorf("Quantile(0.9) = %v, want 0 for an objective the summary wasn't created with", got)
+	}
+}
+
+func TestSummaryLabels(t *testing.T) {
+	mf := MetricFactory{Registerer: prometheus.NewRegistry()}
+	s := mf.NewSummary("test_summary_labels", "help", []string{"label"}, nil, 0, 0)
+
+	s.Observe(5.0, "a")
+	s.Observe(10.0, "b")
+
+	if count, sum := s.Info("a"); count != 1 || sum != 5.0 {
+		t.Errorf("Info(\"a\") = (%d, %v), want (1, 5.0)", count, sum)
+	}
+	if count, sum := s.Info("b"); count != 1 || sum != 10.0 {
+		t.Errorf("Info(\"b\") = (%d, %v), want (1, 10.0)", count, sum)
+	}
+}
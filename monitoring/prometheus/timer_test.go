@@ -0,0 +1,51 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHistogramTimerObserveDuration(t *testing.T) {
+	mf := MetricFactory{Registerer: prometheus.NewRegistry()}
+	h := mf.NewHistogram("test_histogram_timer", "help", nil)
+
+	timer := h.Timer()
+	time.Sleep(time.Millisecond)
+	d := timer.ObserveDuration()
+	if d <= 0 {
+		t.Errorf("ObserveDuration() = %v, want > 0", d)
+	}
+
+	if count, sum := h.Info(); count != 1 || sum <= 0 {
+		t.Errorf("Info() = (%d, %v), want (1, >0)", count, sum)
+	}
+}
+
+func TestSummaryTimerStop(t *testing.T) {
+	mf := MetricFactory{Registerer: prometheus.NewRegistry()}
+	s := mf.NewSummary("test_summary_timer", "help", nil, nil, 0, 0)
+
+	timer := s.Timer()
+	time.Sleep(time.Millisecond)
+	timer.Stop()
+
+	if count, sum := s.Info(); count != 1 || sum <= 0 {
+		t.Errorf("Info() = (%d, %v), want (1, >0)", count, sum)
+	}
+}